@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcListener is one net.Listener paired with the grpc.Server that should
+// serve it; TCP listeners get their own server instance so each can carry
+// its own TLS credentials and interceptors while registering the same
+// VGService/LVService/health services.
+type grpcListener struct {
+	address string
+	net     net.Listener
+	server  *grpc.Server
+}
+
+// newGRPCListeners turns the configured endpoints into servable listeners.
+// With no listeners configured, it falls back to the legacy single UNIX
+// socket at cfg.SocketName for backward compatibility. Every listener's
+// server is built with tracker's interceptors so in-flight RPCs can be
+// counted and named during a graceful shutdown.
+func newGRPCListeners(cfg Config, tracker *inflightTracker) ([]grpcListener, error) {
+	entries := cfg.Listeners
+	if len(entries) == 0 {
+		entries = []ListenerConfig{{Type: "unix", Address: cfg.SocketName}}
+	}
+
+	listeners := make([]grpcListener, 0, len(entries))
+	for _, lc := range entries {
+		switch lc.Type {
+		case "unix":
+			lis, err := listenUnix(lc.Address)
+			if err != nil {
+				return nil, fmt.Errorf("listener %q: %w", lc.Address, err)
+			}
+			opts := tracker.serverOptions()
+			listeners = append(listeners, grpcListener{address: lc.Address, net: lis, server: grpc.NewServer(opts...)})
+		case "tcp":
+			lis, err := net.Listen("tcp", lc.Address)
+			if err != nil {
+				return nil, fmt.Errorf("listener %q: %w", lc.Address, err)
+			}
+			opts, err := tcpServerOptions(lc.TLS)
+			if err != nil {
+				return nil, fmt.Errorf("listener %q: %w", lc.Address, err)
+			}
+			opts = append(tracker.serverOptions(), opts...)
+			listeners = append(listeners, grpcListener{address: lc.Address, net: lis, server: grpc.NewServer(opts...)})
+		default:
+			return nil, fmt.Errorf("listener %q: unknown type %q", lc.Address, lc.Type)
+		}
+	}
+	return listeners, nil
+}
+
+// listenUnix removes a stale socket file, if any, before binding to it.
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+func tcpServerOptions(tlsCfg *ListenerTLSConfig) ([]grpc.ServerOption, error) {
+	if tlsCfg == nil {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS cert/key: %w", err)
+	}
+	tc := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if tlsCfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", tlsCfg.ClientCAFile)
+		}
+		tc.ClientCAs = pool
+		if tlsCfg.RequireClientCert {
+			tc.ClientAuth = tls.RequireAndVerifyClientCert
+		} else {
+			tc.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	opts := []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tc))}
+	if len(tlsCfg.ClientAllowlist) > 0 {
+		opts = append(opts, grpc.UnaryInterceptor(deviceClassAllowlistInterceptor(tlsCfg.ClientAllowlist)))
+	}
+	return opts, nil
+}
+
+// deviceClassRequest is implemented by proto request messages that target a
+// single device class (CreateLV, ResizeLV, RemoveLV, and so on).
+type deviceClassRequest interface {
+	GetDeviceClass() string
+}
+
+// deviceClassAllowlistInterceptor rejects RPCs whose caller's client
+// certificate identity is not on the allowlist for the device class the
+// request targets, so that a TLS-authenticated but unauthorized node can't
+// manipulate another node's volume group. Requests that don't target a
+// specific device class (e.g. GetLVList) pass through unchecked.
+func deviceClassAllowlistInterceptor(allowlist map[string][]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		dcReq, ok := req.(deviceClassRequest)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		allowed, restricted := allowlist[dcReq.GetDeviceClass()]
+		if !restricted {
+			return handler(ctx, req)
+		}
+
+		identity, err := peerIdentity(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		for _, name := range allowed {
+			if name == identity {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "client %q is not allowed to access device class %q", identity, dcReq.GetDeviceClass())
+	}
+}
+
+// peerIdentity returns the client certificate's CommonName, falling back to
+// its first DNS SAN, for a TLS-authenticated peer.
+func peerIdentity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", errors.New("no peer information in context")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return "", errors.New("client did not present a certificate")
+	}
+	cert := tlsInfo.State.PeerCertificates[0]
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName, nil
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0], nil
+	}
+	return "", errors.New("client certificate has no usable CN or SAN")
+}
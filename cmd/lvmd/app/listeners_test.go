@@ -0,0 +1,240 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TestNewGRPCListenersDefaultsToUnixSocket asserts the empty-config fallback
+// newGRPCListeners documents: no listeners: entries means exactly one UNIX
+// socket at cfg.SocketName, not zero listeners.
+func TestNewGRPCListenersDefaultsToUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "lvmd.sock")
+
+	listeners, err := newGRPCListeners(Config{SocketName: sock}, newInflightTracker())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, l := range listeners {
+			l.net.Close()
+		}
+	}()
+
+	if len(listeners) != 1 {
+		t.Fatalf("got %d listeners, want 1: %+v", len(listeners), listeners)
+	}
+	if listeners[0].address != sock {
+		t.Fatalf("got address %q, want %q", listeners[0].address, sock)
+	}
+}
+
+// TestNewGRPCListenersRejectsUnknownType asserts a typo'd listener type fails
+// fast at startup instead of silently serving nothing for that entry.
+func TestNewGRPCListenersRejectsUnknownType(t *testing.T) {
+	_, err := newGRPCListeners(Config{Listeners: []ListenerConfig{{Type: "quic", Address: "127.0.0.1:0"}}}, newInflightTracker())
+	if err == nil {
+		t.Fatal("expected an error for an unknown listener type")
+	}
+}
+
+// TestTCPServerOptionsNilConfig asserts a "tcp" listener with no tls: block
+// gets no extra server options (plaintext), matching how newGRPCListeners
+// merges tcpServerOptions' result into the tracker's own options.
+func TestTCPServerOptionsNilConfig(t *testing.T) {
+	opts, err := tcpServerOptions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts != nil {
+		t.Fatalf("got %d options, want none for a nil TLS config", len(opts))
+	}
+}
+
+// TestTCPServerOptionsLoadsCertificate asserts a valid cert/key pair produces
+// exactly the grpc.Creds server option, with no client allowlist interceptor
+// since no client-allowlist was configured.
+func TestTCPServerOptionsLoadsCertificate(t *testing.T) {
+	certFile, keyFile := writeTestCertPair(t, "lvmd-test")
+
+	opts, err := tcpServerOptions(&ListenerTLSConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 1 {
+		t.Fatalf("got %d server options, want 1 (credentials only)", len(opts))
+	}
+}
+
+// TestTCPServerOptionsAddsAllowlistInterceptorWithClientCA asserts that
+// configuring a client-allowlist alongside a client CA wires up the
+// deviceClassAllowlistInterceptor as a second server option.
+func TestTCPServerOptionsAddsAllowlistInterceptorWithClientCA(t *testing.T) {
+	certFile, keyFile := writeTestCertPair(t, "lvmd-test")
+	caFile, _ := writeTestCertPair(t, "lvmd-test-ca")
+
+	opts, err := tcpServerOptions(&ListenerTLSConfig{
+		CertFile:        certFile,
+		KeyFile:         keyFile,
+		ClientCAFile:    caFile,
+		ClientAllowlist: map[string][]string{"ssd": {"node-a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 2 {
+		t.Fatalf("got %d server options, want 2 (credentials + allowlist interceptor)", len(opts))
+	}
+}
+
+// TestTCPServerOptionsRejectsMissingCert asserts a misconfigured cert/key
+// path fails tcpServerOptions instead of silently starting a TCP listener
+// with no TLS.
+func TestTCPServerOptionsRejectsMissingCert(t *testing.T) {
+	_, err := tcpServerOptions(&ListenerTLSConfig{CertFile: "/does/not/exist.pem", KeyFile: "/does/not/exist-key.pem"})
+	if err == nil {
+		t.Fatal("expected an error for a missing cert/key pair")
+	}
+}
+
+type fakeDeviceClassRequest struct{ deviceClass string }
+
+func (r fakeDeviceClassRequest) GetDeviceClass() string { return r.deviceClass }
+
+func echoHandler(ctx context.Context, req any) (any, error) { return req, nil }
+
+func contextWithClientCN(cn string) context.Context {
+	info := credentials.TLSInfo{}
+	if cn != "" {
+		info.State = tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}}}
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+// TestDeviceClassAllowlistInterceptorAllowsListedIdentity asserts a client
+// whose certificate CN is on the device class's allowlist reaches the
+// handler.
+func TestDeviceClassAllowlistInterceptorAllowsListedIdentity(t *testing.T) {
+	interceptor := deviceClassAllowlistInterceptor(map[string][]string{"ssd": {"node-a"}})
+
+	_, err := interceptor(contextWithClientCN("node-a"), fakeDeviceClassRequest{"ssd"}, &grpc.UnaryServerInfo{}, echoHandler)
+	if err != nil {
+		t.Fatalf("expected an allowed identity to reach the handler, got %v", err)
+	}
+}
+
+// TestDeviceClassAllowlistInterceptorDeniesUnlistedIdentity asserts a client
+// with a valid certificate whose CN is not on the allowlist for the
+// requested device class is rejected with PermissionDenied, not some other
+// error an unauthorized caller could confuse with a transient failure.
+func TestDeviceClassAllowlistInterceptorDeniesUnlistedIdentity(t *testing.T) {
+	interceptor := deviceClassAllowlistInterceptor(map[string][]string{"ssd": {"node-a"}})
+
+	_, err := interceptor(contextWithClientCN("node-b"), fakeDeviceClassRequest{"ssd"}, &grpc.UnaryServerInfo{}, echoHandler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("got code %v, want PermissionDenied", status.Code(err))
+	}
+}
+
+// TestDeviceClassAllowlistInterceptorRejectsMissingClientCert asserts a
+// caller that never presented a client certificate gets Unauthenticated
+// rather than PermissionDenied: it never had an identity to check against
+// the allowlist in the first place.
+func TestDeviceClassAllowlistInterceptorRejectsMissingClientCert(t *testing.T) {
+	interceptor := deviceClassAllowlistInterceptor(map[string][]string{"ssd": {"node-a"}})
+
+	_, err := interceptor(contextWithClientCN(""), fakeDeviceClassRequest{"ssd"}, &grpc.UnaryServerInfo{}, echoHandler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("got code %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+// TestDeviceClassAllowlistInterceptorPassesThroughUnrestrictedDeviceClass
+// asserts a device class with no allowlist entry is left unrestricted, even
+// for an unauthenticated caller.
+func TestDeviceClassAllowlistInterceptorPassesThroughUnrestrictedDeviceClass(t *testing.T) {
+	interceptor := deviceClassAllowlistInterceptor(map[string][]string{"ssd": {"node-a"}})
+
+	_, err := interceptor(contextWithClientCN(""), fakeDeviceClassRequest{"hdd"}, &grpc.UnaryServerInfo{}, echoHandler)
+	if err != nil {
+		t.Fatalf("expected an unrestricted device class to pass through, got %v", err)
+	}
+}
+
+// TestDeviceClassAllowlistInterceptorPassesThroughNonDeviceClassRequest
+// asserts RPCs like GetLVList, which don't target a single device class,
+// are never checked against the allowlist at all.
+func TestDeviceClassAllowlistInterceptorPassesThroughNonDeviceClassRequest(t *testing.T) {
+	interceptor := deviceClassAllowlistInterceptor(map[string][]string{"ssd": {"node-a"}})
+
+	_, err := interceptor(context.Background(), struct{}{}, &grpc.UnaryServerInfo{}, echoHandler)
+	if err != nil {
+		t.Fatalf("expected a non-device-class request to pass through, got %v", err)
+	}
+}
+
+// writeTestCertPair generates a throwaway self-signed ECDSA cert/key pair
+// under t.TempDir and returns their PEM file paths, for exercising
+// tcpServerOptions' cert-loading path without a real CA.
+func writeTestCertPair(t *testing.T, cn string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,15 +2,17 @@ package app
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"github.com/spf13/cobra"
 	"github.com/topolvm/topolvm"
@@ -21,6 +23,7 @@ import (
 	"go.elastic.co/ecszap"
 	uberzap "go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -33,6 +36,14 @@ var (
 	lvmPath          string
 	zapOpts          zap.Options
 	ECSFormatLogging bool
+
+	tcpListenAddress  string
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsClientCAFile   string
+	requireClientCert bool
+
+	gracefulShutdownTimeout time.Duration
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -62,6 +73,48 @@ func subMain(ctx context.Context) error {
 	command.Containerized(containerized)
 	command.SetLVMPath(lvmPath)
 
+	rootCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		runCtx, cancel := context.WithCancelCause(rootCtx)
+		err := runServer(runCtx, cancel, logger)
+		cancel(nil)
+
+		if errors.Is(context.Cause(runCtx), lvmd.ErrConfigModified) {
+			logger.Info("config file changed, reloading lvmd")
+			continue
+		}
+		return err
+	}
+}
+
+// parseAndValidateConfigStructure parses cfgFilePath and runs
+// lvmd.ValidateDeviceClasses against it, without touching the package-level
+// config or the host. This is the subset of runServer's own startup
+// validation that watchConfigFile can cheaply re-run on every candidate
+// edit to decide whether it's even worth tearing down the current
+// generation; it deliberately excludes the volume-group/thin-pool existence
+// check below, which needs a live LVM query and is left to runServer itself
+// once the new generation actually starts. A config whose volume group or
+// thin pool has disappeared on disk is consequently still only caught after
+// the swap, the one case this fix doesn't close.
+func parseAndValidateConfigStructure(cfgFilePath string) (Config, error) {
+	cfg, err := parseConfFile(cfgFilePath)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := lvmd.ValidateDeviceClasses(cfg.DeviceClasses); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// runServer loads the config, validates it against the volume groups present
+// on the host, serves the gRPC API on every configured listener, and blocks until
+// ctx is cancelled. A cancellation whose cause is lvmd.ErrConfigModified asks
+// the caller (subMain) to start a fresh generation with the new config.
+func runServer(ctx context.Context, cancel context.CancelCauseFunc, logger logr.Logger) error {
 	if err := loadConfFile(ctx, cfgFilePath); err != nil {
 		return err
 	}
@@ -92,26 +145,31 @@ func subMain(ctx context.Context) error {
 		}
 	}
 
-	// UNIX domain socket file should be removed before listening.
-	err = os.Remove(config.SocketName)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
+	applyListenerFlags(&config)
 
-	lis, err := net.Listen("unix", config.SocketName)
+	tracker := newInflightTracker()
+	listeners, err := newGRPCListeners(config, tracker)
 	if err != nil {
 		return err
 	}
-	grpcServer := grpc.NewServer()
+
 	dcm := lvmd.NewDeviceClassManager(config.DeviceClasses)
 	ocm := lvmd.NewLvcreateOptionClassManager(config.LvcreateOptionClasses)
-	vgService, notifier := lvmd.NewVGService(dcm)
-	proto.RegisterVGServiceServer(grpcServer, vgService)
-	proto.RegisterLVServiceServer(grpcServer, lvmd.NewLVService(dcm, ocm, notifier))
-	grpc_health_v1.RegisterHealthServer(grpcServer, lvmd.NewHealthService())
+	vgService, notifier := lvmd.NewReloadableVGService(dcm)
+	healthService := health.NewServer()
+	for _, l := range listeners {
+		proto.RegisterVGServiceServer(l.server, vgService)
+		proto.RegisterLVServiceServer(l.server, lvmd.NewLVService(dcm, ocm, notifier))
+		grpc_health_v1.RegisterHealthServer(l.server, healthService)
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	watcher, err := watchConfigFile(logger, cfgFilePath, cancel)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	shutdownTimeout := resolveGracefulShutdownTimeout(config)
 
 	go func() {
 		ticker := time.NewTicker(10 * time.Minute)
@@ -119,7 +177,7 @@ func subMain(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				ticker.Stop()
-				grpcServer.GracefulStop()
+				shutdownServers(logger, listeners, healthService, tracker, shutdownTimeout)
 				return
 			case <-ticker.C:
 				notifier()
@@ -127,7 +185,132 @@ func subMain(ctx context.Context) error {
 		}
 	}()
 
-	return grpcServer.Serve(lis)
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		l := l
+		go func() {
+			errs <- l.server.Serve(l.net)
+		}()
+	}
+
+	for range listeners {
+		if err := <-errs; err != nil && !errors.Is(context.Cause(ctx), lvmd.ErrConfigModified) {
+			// Serve returns an error once GracefulStop closes a listener;
+			// that's expected on a config-triggered restart, not a failure.
+			return err
+		}
+	}
+	return nil
+}
+
+// applyListenerFlags merges the flag-driven TCP+TLS convenience listener, if
+// any was set, into the loaded config. --listen-tcp is documented as serving
+// the gRPC API "alongside" the UNIX socket, so when the YAML listeners:
+// section was empty (the common case), the legacy UNIX socket is seeded
+// explicitly first instead of relying on newGRPCListeners' empty-config
+// fallback, which would otherwise be shadowed the moment this appends a TCP
+// entry. The YAML listeners: section remains the way to configure more than
+// one non-default endpoint, or to opt out of the UNIX socket entirely.
+func applyListenerFlags(cfg *Config) {
+	if tcpListenAddress == "" {
+		return
+	}
+
+	if len(cfg.Listeners) == 0 {
+		cfg.Listeners = append(cfg.Listeners, ListenerConfig{Type: "unix", Address: cfg.SocketName})
+	}
+
+	lc := ListenerConfig{Type: "tcp", Address: tcpListenAddress}
+	if tlsCertFile != "" || tlsKeyFile != "" {
+		lc.TLS = &ListenerTLSConfig{
+			CertFile:          tlsCertFile,
+			KeyFile:           tlsKeyFile,
+			ClientCAFile:      tlsClientCAFile,
+			RequireClientCert: requireClientCert,
+		}
+	}
+	cfg.Listeners = append(cfg.Listeners, lc)
+}
+
+// resolveGracefulShutdownTimeout prefers the YAML field, if set, over the
+// --graceful-shutdown-timeout flag, so a config reload can change it without
+// a restart.
+func resolveGracefulShutdownTimeout(cfg Config) time.Duration {
+	if cfg.GracefulShutdownTimeoutSeconds == nil {
+		return gracefulShutdownTimeout
+	}
+	seconds := *cfg.GracefulShutdownTimeoutSeconds
+	if seconds < 0 {
+		return gracefulShutdownWaitForever
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// configMapDataSymlink is the hidden directory entry a Kubernetes
+// ConfigMap volume swaps atomically (via rename) on every update. The
+// mounted file itself (e.g. lvmd.yaml) is a symlink into it and is never
+// itself written, created, or renamed, so watchConfigFile has to react to
+// this name too or it will never see a ConfigMap-driven update.
+const configMapDataSymlink = "..data"
+
+// watchConfigFile watches cfgFilePath for writes/renames (editors commonly
+// replace a file rather than write in place, and a Kubernetes ConfigMap
+// mount swaps configMapDataSymlink instead of touching cfgFilePath at all)
+// and, once a change settles, cancels ctx with lvmd.ErrConfigModified so
+// runServer's caller can reload with the new config. A change that doesn't
+// parse or fails lvmd.ValidateDeviceClasses is logged and left running on
+// the last-known-good generation instead of cancelling: ReloadVolumes-style
+// "no pod restart" only holds if a bad edit can't take the daemon down.
+func watchConfigFile(logger logr.Logger, cfgFilePath string, cancel context.CancelCauseFunc) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(cfgFilePath)
+	name := filepath.Base(cfgFilePath)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		const debounce = 500 * time.Millisecond
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				base := filepath.Base(event.Name)
+				if base != name && base != configMapDataSymlink {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(debounce, func() {
+						if _, err := parseAndValidateConfigStructure(cfgFilePath); err != nil {
+							logger.Error(err, "config file changed but the new config is invalid; keeping the last-known-good config running")
+							return
+						}
+						cancel(lvmd.ErrConfigModified)
+					})
+				} else {
+					timer.Reset(debounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error(err, "config file watcher error")
+			}
+		}
+	}()
+
+	return watcher, nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -145,6 +328,13 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&containerized, "container", false, "Run within a container")
 	rootCmd.PersistentFlags().StringVar(&lvmPath, "lvm-path", "", "lvm command path on the host OS")
 	rootCmd.PersistentFlags().BoolVar(&ECSFormatLogging, "ecs-format-logging", false, "Enable Elastic Common Schema (ECS) format logging")
+	rootCmd.PersistentFlags().StringVar(&tcpListenAddress, "listen-tcp", "", "additional host:port to serve the gRPC API on, alongside the UNIX socket")
+	rootCmd.PersistentFlags().StringVar(&tlsCertFile, "tls-cert-file", "", "TLS certificate file for --listen-tcp")
+	rootCmd.PersistentFlags().StringVar(&tlsKeyFile, "tls-key-file", "", "TLS private key file for --listen-tcp")
+	rootCmd.PersistentFlags().StringVar(&tlsClientCAFile, "tls-client-ca-file", "", "CA file used to verify client certificates on --listen-tcp")
+	rootCmd.PersistentFlags().BoolVar(&requireClientCert, "tls-require-client-cert", false, "reject --listen-tcp clients that do not present a certificate")
+	rootCmd.PersistentFlags().DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", gracefulShutdownWaitForever,
+		"how long SIGTERM waits for in-flight RPCs to finish before forcing connections closed; -1s waits forever")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
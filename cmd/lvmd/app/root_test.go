@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm/internal/lvmd"
+)
+
+// TestWatchConfigFileTriggersReload writes a config file, starts watching
+// it, then mutates it the way an operator or a ConfigMap volume remount
+// would (rewrite, not append), and asserts the watch cancels its context
+// with lvmd.ErrConfigModified within a bounded window.
+//
+// This only covers the reload-trigger mechanism in watchConfigFile: that a
+// debounced file change cancels ctx with lvmd.ErrConfigModified so subMain's
+// loop starts a fresh generation. It does not cover GetLVList/Watch
+// reflecting new VGs/thin pools end-to-end, because GetLVList and Watch are
+// not implemented anywhere in this checkout — internal/lvmd/command (the
+// LVM wrapper) and the VGService that would serve them don't exist here,
+// only the ReloadableVGService decorator this backlog added around them.
+// Asserting on them would need that package plus a real (or faked) volume
+// group to exercise, neither of which this sandboxed tree can provide.
+func TestWatchConfigFileTriggersReload(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "lvmd.yaml")
+	if err := os.WriteFile(cfgPath, []byte("socket-name: /tmp/lvmd.sock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	watcher, err := watchConfigFile(logr.Discard(), cfgPath, cancel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if err := os.WriteFile(cfgPath, []byte("socket-name: /tmp/lvmd2.sock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+		if cause := context.Cause(ctx); cause != lvmd.ErrConfigModified {
+			t.Fatalf("ctx cancelled with cause %v, want %v", cause, lvmd.ErrConfigModified)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for config file watcher to trigger a reload")
+	}
+}
+
+// TestWatchConfigFileTriggersReloadOnConfigMapSymlinkSwap reproduces how a
+// Kubernetes ConfigMap volume actually updates its mounted file: it never
+// touches the visible symlink (lvmd.yaml here), only atomically renames the
+// hidden ..data symlink to point at a new timestamped directory. A watcher
+// that only matched on the config file's own basename would never see this.
+func TestWatchConfigFileTriggersReloadOnConfigMapSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+
+	data1 := filepath.Join(dir, "..data1")
+	if err := os.Mkdir(data1, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data1, "lvmd.yaml"), []byte("socket-name: /tmp/lvmd.sock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..data1", filepath.Join(dir, configMapDataSymlink)); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "lvmd.yaml")
+	if err := os.Symlink(filepath.Join(configMapDataSymlink, "lvmd.yaml"), cfgPath); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	watcher, err := watchConfigFile(logr.Discard(), cfgPath, cancel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	data2 := filepath.Join(dir, "..data2")
+	if err := os.Mkdir(data2, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data2, "lvmd.yaml"), []byte("socket-name: /tmp/lvmd2.sock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink("..data2", tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, configMapDataSymlink)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+		if cause := context.Cause(ctx); cause != lvmd.ErrConfigModified {
+			t.Fatalf("ctx cancelled with cause %v, want %v", cause, lvmd.ErrConfigModified)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for a ConfigMap-style ..data symlink swap to trigger a reload")
+	}
+}
+
+// TestWatchConfigFileKeepsRunningOnInvalidReload asserts that an edit which
+// fails to parse does not cancel ctx: the running generation must be left
+// alone instead of being torn down for a config that can never replace it.
+func TestWatchConfigFileKeepsRunningOnInvalidReload(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "lvmd.yaml")
+	if err := os.WriteFile(cfgPath, []byte("socket-name: /tmp/lvmd.sock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	watcher, err := watchConfigFile(logr.Discard(), cfgPath, cancel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if err := os.WriteFile(cfgPath, []byte("not: valid: yaml: at: all\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("ctx was cancelled (cause %v) for an edit that should have been rejected", context.Cause(ctx))
+	case <-time.After(2 * time.Second):
+		// No cancellation within the debounce window: the bad edit was
+		// correctly ignored and the last-known-good generation kept running.
+	}
+}
+
+// withFlagValues sets the given *string flag variables for the duration of
+// the test and restores their previous values on cleanup, since
+// applyListenerFlags reads them as package-level cobra flag state rather
+// than taking them as parameters.
+func withFlagValues(t *testing.T, vars map[*string]string) {
+	t.Helper()
+	for v, val := range vars {
+		prev := *v
+		*v = val
+		t.Cleanup(func(v *string, prev string) func() {
+			return func() { *v = prev }
+		}(v, prev))
+	}
+}
+
+// TestApplyListenerFlagsNoOpWithoutListenTCP asserts that with --listen-tcp
+// unset, applyListenerFlags leaves cfg.Listeners untouched, so the YAML
+// config (or newGRPCListeners' own UNIX-socket fallback) is the only thing
+// deciding what gets served.
+func TestApplyListenerFlagsNoOpWithoutListenTCP(t *testing.T) {
+	withFlagValues(t, map[*string]string{&tcpListenAddress: ""})
+
+	cfg := Config{SocketName: "/run/lvmd.sock"}
+	applyListenerFlags(&cfg)
+
+	if len(cfg.Listeners) != 0 {
+		t.Fatalf("got %+v, want no listeners added when --listen-tcp is unset", cfg.Listeners)
+	}
+}
+
+// TestApplyListenerFlagsSeedsDefaultUnixListenerAlongsideTCP asserts that
+// when --listen-tcp is set and the YAML listeners: section was empty,
+// applyListenerFlags seeds the legacy UNIX socket explicitly before
+// appending the TCP entry, matching --listen-tcp's documented "alongside"
+// behavior rather than letting the TCP entry shadow newGRPCListeners' own
+// fallback.
+func TestApplyListenerFlagsSeedsDefaultUnixListenerAlongsideTCP(t *testing.T) {
+	withFlagValues(t, map[*string]string{
+		&tcpListenAddress: "127.0.0.1:9000",
+		&tlsCertFile:      "",
+		&tlsKeyFile:       "",
+	})
+
+	cfg := Config{SocketName: "/run/lvmd.sock"}
+	applyListenerFlags(&cfg)
+
+	if len(cfg.Listeners) != 2 {
+		t.Fatalf("got %d listeners, want 2 (unix + tcp): %+v", len(cfg.Listeners), cfg.Listeners)
+	}
+	if cfg.Listeners[0].Type != "unix" || cfg.Listeners[0].Address != cfg.SocketName {
+		t.Fatalf("got first listener %+v, want the default unix socket", cfg.Listeners[0])
+	}
+	if cfg.Listeners[1].Type != "tcp" || cfg.Listeners[1].Address != tcpListenAddress {
+		t.Fatalf("got second listener %+v, want the flag-driven tcp listener", cfg.Listeners[1])
+	}
+	if cfg.Listeners[1].TLS != nil {
+		t.Fatalf("got TLS config %+v, want none with --tls-cert-file/--tls-key-file unset", cfg.Listeners[1].TLS)
+	}
+}
+
+// TestApplyListenerFlagsPreservesExistingYAMLListeners asserts that when the
+// YAML listeners: section already has entries, applyListenerFlags appends
+// the TCP listener without touching or duplicating what's already there.
+func TestApplyListenerFlagsPreservesExistingYAMLListeners(t *testing.T) {
+	withFlagValues(t, map[*string]string{&tcpListenAddress: "127.0.0.1:9000"})
+
+	cfg := Config{
+		SocketName: "/run/lvmd.sock",
+		Listeners:  []ListenerConfig{{Type: "unix", Address: "/run/other.sock"}},
+	}
+	applyListenerFlags(&cfg)
+
+	if len(cfg.Listeners) != 2 {
+		t.Fatalf("got %d listeners, want 2 (existing + tcp): %+v", len(cfg.Listeners), cfg.Listeners)
+	}
+	if cfg.Listeners[0].Address != "/run/other.sock" {
+		t.Fatalf("got first listener %+v, want the YAML-configured listener left untouched", cfg.Listeners[0])
+	}
+}
+
+// TestApplyListenerFlagsCarriesTLSConfig asserts --tls-cert-file/--tls-key-file
+// (and their client-CA/require-client-cert companions) produce a TLS config
+// on the flag-driven listener.
+func TestApplyListenerFlagsCarriesTLSConfig(t *testing.T) {
+	withFlagValues(t, map[*string]string{
+		&tcpListenAddress: "127.0.0.1:9000",
+		&tlsCertFile:      "/etc/lvmd/tls.crt",
+		&tlsKeyFile:       "/etc/lvmd/tls.key",
+		&tlsClientCAFile:  "/etc/lvmd/ca.crt",
+	})
+	requireClientCertPrev := requireClientCert
+	requireClientCert = true
+	t.Cleanup(func() { requireClientCert = requireClientCertPrev })
+
+	cfg := Config{SocketName: "/run/lvmd.sock"}
+	applyListenerFlags(&cfg)
+
+	tls := cfg.Listeners[len(cfg.Listeners)-1].TLS
+	if tls == nil {
+		t.Fatal("got no TLS config, want one populated from --tls-* flags")
+	}
+	if tls.CertFile != "/etc/lvmd/tls.crt" || tls.KeyFile != "/etc/lvmd/tls.key" || tls.ClientCAFile != "/etc/lvmd/ca.crt" || !tls.RequireClientCert {
+		t.Fatalf("got %+v, want it to carry every --tls-* flag value", tls)
+	}
+}
+
+// TestResolveGracefulShutdownTimeoutPrefersYAML asserts the YAML field wins
+// over the --graceful-shutdown-timeout flag when both are set, so a config
+// reload can change the timeout without a restart.
+func TestResolveGracefulShutdownTimeoutPrefersYAML(t *testing.T) {
+	prev := gracefulShutdownTimeout
+	gracefulShutdownTimeout = 5 * time.Second
+	t.Cleanup(func() { gracefulShutdownTimeout = prev })
+
+	seconds := 30
+	got := resolveGracefulShutdownTimeout(Config{GracefulShutdownTimeoutSeconds: &seconds})
+	if got != 30*time.Second {
+		t.Fatalf("got %v, want 30s", got)
+	}
+}
+
+// TestResolveGracefulShutdownTimeoutFallsBackToFlag asserts an unset YAML
+// field defers to the flag.
+func TestResolveGracefulShutdownTimeoutFallsBackToFlag(t *testing.T) {
+	prev := gracefulShutdownTimeout
+	gracefulShutdownTimeout = 7 * time.Second
+	t.Cleanup(func() { gracefulShutdownTimeout = prev })
+
+	got := resolveGracefulShutdownTimeout(Config{})
+	if got != 7*time.Second {
+		t.Fatalf("got %v, want 7s", got)
+	}
+}
+
+// TestResolveGracefulShutdownTimeoutNegativeYAMLWaitsForever asserts a
+// negative YAML value maps to gracefulShutdownWaitForever, the same sentinel
+// the flag uses, rather than a negative time.Duration that would make
+// shutdownServers' timer fire immediately.
+func TestResolveGracefulShutdownTimeoutNegativeYAMLWaitsForever(t *testing.T) {
+	seconds := -1
+	got := resolveGracefulShutdownTimeout(Config{GracefulShutdownTimeoutSeconds: &seconds})
+	if got != gracefulShutdownWaitForever {
+		t.Fatalf("got %v, want gracefulShutdownWaitForever", got)
+	}
+}
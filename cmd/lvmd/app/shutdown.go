@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// gracefulShutdownWaitForever is the -1 sentinel for --graceful-shutdown-timeout
+// and the equivalent YAML field: never fall back to Stop(), however long
+// GracefulStop takes.
+const gracefulShutdownWaitForever = -1 * time.Second
+
+// inflightTracker counts in-flight RPCs per full method name across every
+// listener's server, so a shutdown that times out can log what was still
+// running when it gave up.
+type inflightTracker struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{inFlight: make(map[string]int)}
+}
+
+// serverOptions returns the unary and stream interceptors that feed this
+// tracker; every grpc.Server lvmd creates should include them.
+func (t *inflightTracker) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(t.unaryInterceptor),
+		grpc.ChainStreamInterceptor(t.streamInterceptor),
+	}
+}
+
+func (t *inflightTracker) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	t.enter(info.FullMethod)
+	defer t.leave(info.FullMethod)
+	return handler(ctx, req)
+}
+
+func (t *inflightTracker) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	t.enter(info.FullMethod)
+	defer t.leave(info.FullMethod)
+	return handler(srv, ss)
+}
+
+func (t *inflightTracker) enter(method string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight[method]++
+}
+
+func (t *inflightTracker) leave(method string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight[method]--
+	if t.inFlight[method] <= 0 {
+		delete(t.inFlight, method)
+	}
+}
+
+// snapshot returns a copy of the current per-method in-flight counts.
+func (t *inflightTracker) snapshot() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]int, len(t.inFlight))
+	for method, n := range t.inFlight {
+		out[method] = n
+	}
+	return out
+}
+
+// shutdownServers marks health as NOT_SERVING, then gives every listener's
+// server up to timeout to finish in-flight RPCs via GracefulStop before
+// forcing the connections closed with Stop(). timeout ==
+// gracefulShutdownWaitForever waits indefinitely, matching lvmd's prior,
+// unbounded behavior.
+func shutdownServers(logger logr.Logger, listeners []grpcListener, health *health.Server, tracker *inflightTracker, timeout time.Duration) {
+	health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, l := range listeners {
+			wg.Add(1)
+			go func(l grpcListener) {
+				defer wg.Done()
+				l.server.GracefulStop()
+			}(l)
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	if timeout == gracefulShutdownWaitForever {
+		<-done
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Info("graceful shutdown timed out, forcing remaining connections closed", "inFlightRPCs", tracker.snapshot())
+		for _, l := range listeners {
+			l.server.Stop()
+		}
+		<-done
+	}
+}
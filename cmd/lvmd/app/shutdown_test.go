@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// TestInflightTrackerCountsAndClearsPerMethod asserts enter/leave track a
+// count per full method name and clean themselves up once a method has no
+// RPCs left in flight, since shutdownServers' timeout log relies on
+// snapshot() only reporting methods that are actually still running.
+func TestInflightTrackerCountsAndClearsPerMethod(t *testing.T) {
+	tracker := newInflightTracker()
+
+	tracker.enter("/VGService/CreateLV")
+	tracker.enter("/VGService/CreateLV")
+	tracker.enter("/VGService/Watch")
+
+	snap := tracker.snapshot()
+	if snap["/VGService/CreateLV"] != 2 {
+		t.Fatalf("got %d in-flight CreateLV, want 2", snap["/VGService/CreateLV"])
+	}
+	if snap["/VGService/Watch"] != 1 {
+		t.Fatalf("got %d in-flight Watch, want 1", snap["/VGService/Watch"])
+	}
+
+	tracker.leave("/VGService/CreateLV")
+	tracker.leave("/VGService/CreateLV")
+	tracker.leave("/VGService/Watch")
+
+	snap = tracker.snapshot()
+	if len(snap) != 0 {
+		t.Fatalf("got %v, want an empty snapshot once every RPC has left", snap)
+	}
+}
+
+// TestShutdownServersReturnsPromptlyWithNoInflightRPCs asserts the common
+// case: nothing is in flight, so GracefulStop finishes well under the
+// timeout and shutdownServers doesn't wait for it.
+func TestShutdownServersReturnsPromptlyWithNoInflightRPCs(t *testing.T) {
+	tracker := newInflightTracker()
+	lis, server := newTestGRPCServer(t, tracker)
+
+	start := time.Now()
+	shutdownServers(logr.Discard(), []grpcListener{{net: lis, server: server}}, health.NewServer(), tracker, 5*time.Second)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdownServers took %v with nothing in flight, want well under the 5s timeout", elapsed)
+	}
+}
+
+// TestShutdownServersForcesStopOnTimeout holds a streaming RPC open past
+// shutdownServers' timeout and asserts it falls through to Stop() instead of
+// blocking forever on GracefulStop, which is the whole point of the timeout
+// parameter.
+func TestShutdownServersForcesStopOnTimeout(t *testing.T) {
+	tracker := newInflightTracker()
+	lis, server := newTestGRPCServer(t, tracker)
+
+	conn, err := grpc.NewClient("unix://"+lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Block until the Watch RPC has actually reached the server and sent its
+	// first status, so GracefulStop has something in flight to wait on.
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	shutdownServers(logr.Discard(), []grpcListener{{net: lis, server: server}}, health.NewServer(), tracker, 200*time.Millisecond)
+	elapsed := time.Since(start)
+	if elapsed > 2*time.Second {
+		t.Fatalf("shutdownServers took %v, want it to force Stop() near the 200ms timeout instead of blocking on the open stream", elapsed)
+	}
+}
+
+// newTestGRPCServer starts a real grpc.Server, with tracker's interceptors
+// and the health service registered, on a UNIX socket under t.TempDir.
+func newTestGRPCServer(t *testing.T, tracker *inflightTracker) (net.Listener, *grpc.Server) {
+	t.Helper()
+
+	lis, err := net.Listen("unix", filepath.Join(t.TempDir(), "shutdown-test.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(tracker.serverOptions()...)
+	healthService := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthService)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis, server
+}
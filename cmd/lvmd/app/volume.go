@@ -0,0 +1,300 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Tags TopoLVM sets on every LV it creates, used here to recognize
+// TopoLVM-managed volumes and to recover which PVC/PV they back.
+const (
+	lvTagPVCName      = "topolvm.io/pvc-name"
+	lvTagPVCNamespace = "topolvm.io/pvc-namespace"
+	lvTagPVName       = "topolvm.io/pv-name"
+)
+
+var (
+	volumePruneDryRun  bool
+	volumePruneKubeCfg string
+	volumeRmForce      bool
+)
+
+// volumeCmd is the `lvmd volume` subtree. It talks to the device classes
+// configured on this node directly, the same way runServer does, so an
+// operator can inspect or fix up state without the controller or kubelet
+// being reachable.
+//
+// Because these subcommands run out-of-band in a separate CLI process, they
+// can't call the live daemon's notifier() the way an in-process RPC would:
+// a change made here (e.g. `rm`) won't reach Watch subscribers until lvmd's
+// own 10-minute keep-alive tick. Run `lvmd volume reload` afterwards to
+// reconcile it into the daemon's state immediately.
+var volumeCmd = &cobra.Command{
+	Use:   "volume",
+	Short: "inspect and manage LVM volumes known to this lvmd's device classes",
+	// lvmd itself applies these in subMain before touching the command
+	// package; the volume subcommands bypass subMain, so they have to apply
+	// them here instead or risk running un-containerized lvm calls against a
+	// containerized node.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		command.Containerized(containerized)
+		command.SetLVMPath(lvmPath)
+		return nil
+	},
+}
+
+var volumeLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "list logical volumes across all configured volume groups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := loadConfFile(ctx, cfgFilePath); err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVG\tSIZE\tTHIN-POOL\tPVC")
+		err := forEachVolume(ctx, func(dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume) error {
+			thinPool := "-"
+			if dc.Type == lvmdTypes.TypeThin {
+				thinPool = dc.ThinPoolConfig.Name
+			}
+			pvc := "-"
+			if name, ok := lv.Tags().Get(lvTagPVCName); ok {
+				pvc = name
+				if ns, ok := lv.Tags().Get(lvTagPVCNamespace); ok {
+					pvc = ns + "/" + pvc
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", lv.Name(), dc.VolumeGroup, lv.Size(), thinPool, pvc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	},
+}
+
+var volumeInspectCmd = &cobra.Command{
+	Use:   "inspect LV_NAME",
+	Short: "dump the full LV record as JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := loadConfFile(ctx, cfgFilePath); err != nil {
+			return err
+		}
+
+		lv, err := findVolume(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(lv)
+	},
+}
+
+var volumeReloadAddress string
+
+var volumeReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "ask the running lvmd to rescan its volume groups and print what changed",
+	Long: `reload dials the running lvmd over --address (its configured
+socket-name by default) and invokes the ReloadVolumes RPC, so a manual
+lvremove/vgextend is reconciled into the live daemon's in-memory state and
+broadcast to Watch subscribers immediately instead of waiting for the next
+periodic tick. Unlike the other volume subcommands, reload never touches
+LVM itself; it only reports the diff the daemon computed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := loadConfFile(ctx, cfgFilePath); err != nil {
+			return err
+		}
+
+		address := volumeReloadAddress
+		if address == "" {
+			address = "unix://" + config.SocketName
+		}
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("dialing %s: %w", address, err)
+		}
+		defer conn.Close()
+
+		resp, err := proto.NewVGServiceClient(conn).ReloadVolumes(ctx, &proto.ReloadVolumesRequest{})
+		if err != nil {
+			return fmt.Errorf("calling ReloadVolumes: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANGE\tNAME\tVG\tOLD-SIZE\tNEW-SIZE")
+		for _, d := range resp.Volumes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", d.Change, d.Name, d.VolumeGroup, d.OldSizeBytes, d.NewSizeBytes)
+		}
+		for _, d := range resp.VolumeGroups {
+			fmt.Fprintf(w, "resized\t-\t%s\t%d\t%d\n", d.VolumeGroup, d.OldFreeBytes, d.NewFreeBytes)
+		}
+		return w.Flush()
+	},
+}
+
+var volumePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "list TopoLVM-tagged LVs whose backing PV no longer exists in the cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := loadConfFile(ctx, cfgFilePath); err != nil {
+			return err
+		}
+		if !volumePruneDryRun {
+			return errors.New("only --dry-run is supported for now; remove candidates with `lvmd volume rm`")
+		}
+
+		pvs, err := existingPVNames(ctx, volumePruneKubeCfg)
+		if err != nil {
+			return fmt.Errorf("listing PersistentVolumes: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVG\tPV")
+		err = forEachVolume(ctx, func(dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume) error {
+			pvName, ok := lv.Tags().Get(lvTagPVName)
+			if !ok {
+				return nil
+			}
+			if pvs[pvName] {
+				return nil
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", lv.Name(), dc.VolumeGroup, pvName)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	},
+}
+
+var volumeRmCmd = &cobra.Command{
+	Use:   "rm LV_NAME",
+	Short: "remove a logical volume using the same removal path as LVService.RemoveLV",
+	Long: `rm removes a logical volume directly, out-of-band from the running
+lvmd. It does not notify the daemon, so Watch subscribers won't see the
+removal until lvmd's next periodic tick; run "lvmd volume reload"
+afterwards to reconcile it in immediately.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !volumeRmForce {
+			return errors.New("refusing to remove a volume without --force")
+		}
+
+		ctx := cmd.Context()
+		if err := loadConfFile(ctx, cfgFilePath); err != nil {
+			return err
+		}
+
+		lv, err := findVolume(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return lv.Remove(ctx)
+	},
+}
+
+// forEachVolume iterates every LV in every configured device class's volume
+// group, mirroring the validation loop in runServer so CLI output always
+// matches what the gRPC surface would report.
+func forEachVolume(ctx context.Context, fn func(dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume) error) error {
+	vgs, err := command.ListVolumeGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, dc := range config.DeviceClasses {
+		vg, err := command.SearchVolumeGroupList(vgs, dc.VolumeGroup)
+		if err != nil {
+			return err
+		}
+		lvs, err := vg.ListVolumes(ctx)
+		if err != nil {
+			return err
+		}
+		for _, lv := range lvs {
+			if err := fn(dc, lv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func findVolume(ctx context.Context, name string) (*command.LogicalVolume, error) {
+	var found *command.LogicalVolume
+	err := forEachVolume(ctx, func(_ *lvmdTypes.DeviceClass, lv *command.LogicalVolume) error {
+		if lv.Name() == name {
+			found = lv
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("logical volume %q not found", name)
+	}
+	return found, nil
+}
+
+// existingPVNames returns the set of PersistentVolume names currently known
+// to the cluster described by kubeconfigPath.
+func existingPVNames(ctx context.Context, kubeconfigPath string) (map[string]bool, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	pvList, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(pvList.Items))
+	for _, pv := range pvList.Items {
+		names[pv.Name] = true
+	}
+	return names, nil
+}
+
+func init() {
+	rootCmd.AddCommand(volumeCmd)
+	volumeCmd.AddCommand(volumeLsCmd)
+	volumeCmd.AddCommand(volumeInspectCmd)
+	volumeCmd.AddCommand(volumeReloadCmd)
+	volumeCmd.AddCommand(volumePruneCmd)
+	volumeCmd.AddCommand(volumeRmCmd)
+
+	volumePruneCmd.Flags().BoolVar(&volumePruneDryRun, "dry-run", false, "only list prune candidates, don't remove anything")
+	volumePruneCmd.Flags().StringVar(&volumePruneKubeCfg, "kubeconfig", "", "kubeconfig used to check which PersistentVolumes still exist")
+	volumeRmCmd.Flags().BoolVar(&volumeRmForce, "force", false, "actually remove the volume")
+	volumeReloadCmd.Flags().StringVar(&volumeReloadAddress, "address", "", "address of the running lvmd, e.g. unix:///run/lvmd.sock (defaults to the configured socket-name)")
+}
@@ -0,0 +1,8 @@
+package lvmd
+
+import "errors"
+
+// ErrConfigModified is used as a context cancellation cause when the lvmd
+// config file on disk has changed and the gRPC server needs to be rebuilt
+// against the new device classes.
+var ErrConfigModified = errors.New("lvmd config file modified")
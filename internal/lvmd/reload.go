@@ -0,0 +1,110 @@
+package lvmd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+)
+
+// ReloadableVGService decorates a VGService with a ReloadVolumes RPC that
+// reconciles in-memory assumptions about the volume groups with whatever is
+// actually on disk. It's useful after an operator runs lvremove/vgextend
+// outside TopoLVM, or after block devices are hot-added, when nothing would
+// otherwise tell lvmd to look again before the next Watch tick.
+//
+// NewVGService and the VGServiceServer it returns are not present in this
+// checkout; GetLVList/Watch and the rest of the real service live in
+// internal/lvmd/command plus generated code neither of which this tree
+// carries. ReloadableVGService is written against those as assumed external
+// dependencies, the same way cmd/lvmd/app's RunE bodies already do.
+type ReloadableVGService struct {
+	proto.VGServiceServer
+
+	dcm      *DeviceClassManager
+	notifier func()
+
+	mu          sync.Mutex
+	lastVolumes map[string]uint64 // LV name -> size in bytes, as of the last reload
+	lastFree    map[string]uint64 // VG name -> free bytes, as of the last reload
+}
+
+// NewReloadableVGService wraps NewVGService's result so the returned server
+// also implements ReloadVolumes. It's returned at its concrete type, not
+// narrowed to proto.VGServiceServer, so in-process callers (the gRPC
+// registration in cmd/lvmd/app, and tests) can call ReloadVolumes directly
+// instead of only over the wire. The returned notifier is unchanged and can
+// still be used for the periodic keep-alive broadcast.
+func NewReloadableVGService(dcm *DeviceClassManager) (*ReloadableVGService, func()) {
+	base, notifier := NewVGService(dcm)
+	return &ReloadableVGService{VGServiceServer: base, dcm: dcm, notifier: notifier}, notifier
+}
+
+// ReloadVolumes re-lists every volume group known to the device classes,
+// diffs it against the last observed state, swaps in the new state, and
+// wakes any Watch subscribers. The very first call after lvmd starts has no
+// prior state to diff against, so everything currently present is reported
+// as added.
+func (s *ReloadableVGService) ReloadVolumes(ctx context.Context, _ *proto.ReloadVolumesRequest) (*proto.ReloadVolumesResponse, error) {
+	volumes := make(map[string]uint64)
+	free := make(map[string]uint64)
+	var lvDiffs []*proto.VolumeDiff
+	var vgDiffs []*proto.VolumeGroupDiff
+
+	vgs, err := command.ListVolumeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, dc := range s.dcm.DeviceClasses() {
+		vg, err := command.SearchVolumeGroupList(vgs, dc.VolumeGroup)
+		if err != nil {
+			return nil, err
+		}
+
+		freeBytes, err := vg.Free(ctx)
+		if err != nil {
+			return nil, err
+		}
+		free[dc.VolumeGroup] = freeBytes
+		if oldFree, ok := s.lastFree[dc.VolumeGroup]; ok && oldFree != freeBytes {
+			vgDiffs = append(vgDiffs, &proto.VolumeGroupDiff{
+				VolumeGroup:  dc.VolumeGroup,
+				OldFreeBytes: oldFree,
+				NewFreeBytes: freeBytes,
+			})
+		}
+
+		lvs, err := vg.ListVolumes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, lv := range lvs {
+			volumes[lv.Name()] = lv.Size()
+			oldSize, existed := s.lastVolumes[lv.Name()]
+			switch {
+			case !existed:
+				lvDiffs = append(lvDiffs, &proto.VolumeDiff{Name: lv.Name(), VolumeGroup: dc.VolumeGroup, Change: "added", NewSizeBytes: lv.Size()})
+			case oldSize != lv.Size():
+				lvDiffs = append(lvDiffs, &proto.VolumeDiff{Name: lv.Name(), VolumeGroup: dc.VolumeGroup, Change: "resized", OldSizeBytes: oldSize, NewSizeBytes: lv.Size()})
+			}
+		}
+	}
+
+	for name, oldSize := range s.lastVolumes {
+		if _, stillPresent := volumes[name]; !stillPresent {
+			lvDiffs = append(lvDiffs, &proto.VolumeDiff{Name: name, Change: "removed", OldSizeBytes: oldSize})
+		}
+	}
+
+	s.lastVolumes = volumes
+	s.lastFree = free
+
+	s.notifier()
+
+	return &proto.ReloadVolumesResponse{Volumes: lvDiffs, VolumeGroups: vgDiffs}, nil
+}
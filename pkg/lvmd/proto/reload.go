@@ -0,0 +1,29 @@
+package proto
+
+// ReloadVolumesRequest asks lvmd to re-scan its volume groups instead of
+// waiting for the next Watch tick.
+type ReloadVolumesRequest struct{}
+
+// ReloadVolumesResponse reports what ReloadVolumes found changed on disk
+// since the last scan, so callers can log something more useful than "ok".
+type ReloadVolumesResponse struct {
+	Volumes      []*VolumeDiff
+	VolumeGroups []*VolumeGroupDiff
+}
+
+// VolumeDiff describes one LV that was added, removed, or resized outside
+// of TopoLVM.
+type VolumeDiff struct {
+	Name         string
+	VolumeGroup  string
+	Change       string // "added", "removed", or "resized"
+	OldSizeBytes uint64
+	NewSizeBytes uint64
+}
+
+// VolumeGroupDiff describes a volume group whose free space changed.
+type VolumeGroupDiff struct {
+	VolumeGroup  string
+	OldFreeBytes uint64
+	NewFreeBytes uint64
+}